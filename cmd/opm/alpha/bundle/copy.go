@@ -0,0 +1,31 @@
+package bundle
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+)
+
+// newCopyCmd returns the `opm alpha bundle copy` command, meant to be
+// registered as a subcommand of the existing `opm alpha bundle` command
+// group.
+func newCopyCmd() *cobra.Command {
+	var toTag string
+
+	cmd := &cobra.Command{
+		Use:   "copy <from-image> <to-image>",
+		Short: "Copy a single bundle image to another registry, preserving its digest",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts []bundle.CopyOption
+			if toTag != "" {
+				opts = append(opts, bundle.WithToTag(toTag))
+			}
+			return bundle.CopyFunc(args[0], args[1], opts...)
+		},
+	}
+
+	cmd.Flags().StringVar(&toTag, "to-tag", "", "tag to give the image in the destination registry (default: keep the source tag)")
+
+	return cmd
+}
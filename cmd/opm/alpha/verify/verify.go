@@ -0,0 +1,83 @@
+// Package verify implements the `opm alpha verify` command, which checks
+// an index image's bundles against a cosign signing policy. The index is
+// pulled and the package exported into a temporary directory, which is
+// removed before the command returns. It is meant to be registered as a
+// subcommand of the existing `opm alpha` command group.
+package verify
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/lib/indexer"
+	"github.com/operator-framework/operator-registry/pkg/lib/signature"
+)
+
+// NewCmd returns the `opm alpha verify` command.
+func NewCmd() *cobra.Command {
+	var (
+		indexRef      string
+		packageName   string
+		keyRef        string
+		requireRekor  bool
+		containerTool string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify bundle image signatures for a package in an index",
+		Long: `Pulls an index image, enumerates the bundle images for the given
+package, and fails unless every one of them carries a signature that
+validates against the supplied policy.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVerify(cmd, indexRef, packageName, containerTool, signature.Policy{
+				KeyRef:       keyRef,
+				RequireRekor: requireRekor,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&indexRef, "index", "", "index image to verify")
+	cmd.Flags().StringVar(&packageName, "package", "", "package within the index to verify")
+	cmd.Flags().StringVar(&keyRef, "key", "", "public key file or KMS URI to verify against (keyless verification if empty)")
+	cmd.Flags().BoolVar(&requireRekor, "require-rekor", true, "require a Rekor transparency log entry for each signature")
+	cmd.Flags().StringVar(&containerTool, "container-tool", "none", "tool to pull the index image with (docker, podman, or none)")
+	cmd.MarkFlagRequired("index")
+	cmd.MarkFlagRequired("package")
+
+	return cmd
+}
+
+func runVerify(cmd *cobra.Command, indexRef, packageName, containerTool string, policy signature.Policy) error {
+	logger := logrus.NewEntry(logrus.New())
+	verifier := signature.NewCosignSigner(policy.KeyRef, logger)
+
+	client, err := containertools.NewClient(containertools.NewContainerTool(containerTool))
+	if err != nil {
+		return err
+	}
+
+	downloadPath, err := ioutil.TempDir("", "opm-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(downloadPath)
+
+	exporter := indexer.NewIndexExporter(client, logger, indexer.WithVerification(verifier, policy))
+	if err := exporter.ExportFromIndex(cmd.Context(), indexer.ExportFromIndexRequest{
+		Index:        indexRef,
+		Package:      packageName,
+		DownloadPath: downloadPath,
+	}); err != nil {
+		return fmt.Errorf("verify: %v", err)
+	}
+
+	fmt.Printf("all bundle signatures for package %q in %s verified\n", packageName, indexRef)
+	return nil
+}
@@ -0,0 +1,64 @@
+package index
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/lib/indexer"
+)
+
+// newCopyCmd returns the `opm index copy` command, meant to be registered
+// as a subcommand of the existing `opm index` command group.
+func newCopyCmd() *cobra.Command {
+	var (
+		toTag         string
+		onlyPackages  []string
+		dryRun        bool
+		containerTool string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "copy <from-index> <to-index>",
+		Short: "Mirror an index image and the bundle images it references to another registry",
+		Long: `Copies an index image and every bundle image it references, across every
+package and channel, from one registry to another. Images are copied
+content-addressed, by digest, rather than rebuilt.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := containertools.NewClient(containertools.NewContainerTool(containerTool))
+			if err != nil {
+				return err
+			}
+			copier := indexer.NewIndexCopier(client, logrus.NewEntry(logrus.New()))
+
+			plan, err := copier.CopyIndex(cmd.Context(), indexer.CopyRequest{
+				From:         args[0],
+				To:           args[1],
+				ToTag:        toTag,
+				OnlyPackages: onlyPackages,
+				DryRun:       dryRun,
+			})
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Printf("%s -> %s\n", plan.Index.From, plan.Index.To)
+				for _, entry := range plan.Bundles {
+					fmt.Printf("%s: %s -> %s\n", entry.Package, entry.From, entry.To)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&toTag, "to-tag", "", "tag to give every copied image in the destination registry (default: keep each image's source tag)")
+	cmd.Flags().StringSliceVar(&onlyPackages, "only-packages", nil, "restrict the copy to these packages (default: all packages in the index)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the copy plan without copying anything")
+	cmd.Flags().StringVar(&containerTool, "container-tool", "none", "tool to pull the source index image with (docker, podman, or none)")
+
+	return cmd
+}
@@ -0,0 +1,50 @@
+package containertools
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Client builds, pushes, pulls and inspects bundle and index images
+// against a specific container backend. Every method takes a
+// context.Context so long-running operations can be cancelled, and a
+// progress io.Writer (where applicable) so callers can stream output
+// instead of having it discarded.
+type Client interface {
+	// Login authenticates to registry so subsequent Push/Pull calls
+	// against it succeed.
+	Login(ctx context.Context, registry, username, password string) error
+	// Build builds dockerfile (a path relative to dir) and tags the
+	// result tag. dockerfile must already exist under dir: Build does not
+	// generate one, so bundle/index callers must write it first (see
+	// bundle.GenerateFunc and the generator IndexAdder.AddToIndex calls).
+	Build(ctx context.Context, dir, dockerfile, tag string, progress io.Writer) error
+	// Push pushes a previously built or pulled image tag.
+	Push(ctx context.Context, tag string, progress io.Writer) error
+	// Pull fetches ref and, when downloadPath is non-empty, exports its
+	// filesystem there.
+	Pull(ctx context.Context, ref, downloadPath string) error
+	// Inspect returns metadata about ref.
+	Inspect(ctx context.Context, ref string) (Image, error)
+}
+
+// Image is the metadata Client.Inspect returns about a remote image.
+type Image struct {
+	Digest string
+	Labels map[string]string
+}
+
+// NewClient returns the Client implementation backing tool.
+func NewClient(tool ContainerTool) (Client, error) {
+	switch tool {
+	case DockerTool:
+		return NewDockerClient()
+	case PodmanTool:
+		return NewPodmanClient()
+	case NoneTool:
+		return NewRegistryClient(), nil
+	default:
+		return nil, fmt.Errorf("containertools: unknown container tool %q", tool)
+	}
+}
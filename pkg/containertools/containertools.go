@@ -0,0 +1,31 @@
+package containertools
+
+// ContainerTool identifies the backend used to build, push and pull bundle
+// and index images.
+type ContainerTool string
+
+const (
+	// DockerTool talks to the Docker Engine API (see DockerClient).
+	DockerTool ContainerTool = "docker"
+	// PodmanTool talks to a podman socket via podman's bindings (see
+	// PodmanClient).
+	PodmanTool ContainerTool = "podman"
+	// NoneTool talks to the registry directly via go-containerregistry,
+	// without requiring a container runtime daemon or CLI to be present.
+	NoneTool ContainerTool = "none"
+)
+
+// NewContainerTool normalizes a user-supplied container tool string,
+// defaulting to docker when none is given.
+func NewContainerTool(tool string) ContainerTool {
+	if tool == "" {
+		return DockerTool
+	}
+	return ContainerTool(tool)
+}
+
+// RequiresDaemon reports whether t needs a running docker/podman
+// daemon or socket available on the host.
+func (t ContainerTool) RequiresDaemon() bool {
+	return t != NoneTool
+}
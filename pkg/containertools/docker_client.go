@@ -0,0 +1,162 @@
+package containertools
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// DockerClient implements Client against the Docker Engine API.
+type DockerClient struct {
+	cli  *client.Client
+	auth types.AuthConfig
+}
+
+// NewDockerClient returns a Client backed by the local Docker Engine,
+// configured from the standard DOCKER_HOST/DOCKER_CERT_PATH/DOCKER_TLS_VERIFY
+// environment variables.
+func NewDockerClient() (*DockerClient, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("containertools: creating docker client: %v", err)
+	}
+	return &DockerClient{cli: cli}, nil
+}
+
+// Login records credentials for registry so Push/Pull can authenticate
+// against it.
+func (d *DockerClient) Login(ctx context.Context, registry, username, password string) error {
+	auth := types.AuthConfig{ServerAddress: registry, Username: username, Password: password}
+	if _, err := d.cli.RegistryLogin(ctx, auth); err != nil {
+		return fmt.Errorf("containertools: logging into %s: %v", registry, err)
+	}
+	d.auth = auth
+	return nil
+}
+
+// Build builds dockerfile (relative to dir) and tags the result tag,
+// streaming the engine's build output to progress.
+func (d *DockerClient) Build(ctx context.Context, dir, dockerfile, tag string, progress io.Writer) error {
+	buildCtx, err := tarDir(dir)
+	if err != nil {
+		return fmt.Errorf("containertools: archiving %s: %v", dir, err)
+	}
+
+	resp, err := d.cli.ImageBuild(ctx, buildCtx, types.ImageBuildOptions{
+		Dockerfile: dockerfile,
+		Tags:       []string{tag},
+	})
+	if err != nil {
+		return fmt.Errorf("containertools: building %s: %v", tag, err)
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(progress, resp.Body)
+	return err
+}
+
+// Push pushes tag, streaming the engine's progress to progress.
+func (d *DockerClient) Push(ctx context.Context, tag string, progress io.Writer) error {
+	encodedAuth, err := encodeAuth(d.auth)
+	if err != nil {
+		return err
+	}
+
+	rc, err := d.cli.ImagePush(ctx, tag, types.ImagePushOptions{RegistryAuth: encodedAuth})
+	if err != nil {
+		return fmt.Errorf("containertools: pushing %s: %v", tag, err)
+	}
+	defer rc.Close()
+
+	_, err = io.Copy(progress, rc)
+	return err
+}
+
+// Pull fetches ref and, when downloadPath is non-empty, exports its
+// filesystem there by way of a throwaway container.
+func (d *DockerClient) Pull(ctx context.Context, ref, downloadPath string) error {
+	encodedAuth, err := encodeAuth(d.auth)
+	if err != nil {
+		return err
+	}
+
+	rc, err := d.cli.ImagePull(ctx, ref, types.ImagePullOptions{RegistryAuth: encodedAuth})
+	if err != nil {
+		return fmt.Errorf("containertools: pulling %s: %v", ref, err)
+	}
+	_, err = io.Copy(ioutil.Discard, rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	if downloadPath == "" {
+		return nil
+	}
+	return exportImageFS(ctx, d.cli, ref, downloadPath)
+}
+
+// Inspect returns ref's first repo digest (in repo@sha256:... form, or ref
+// itself when the local image has none) and config labels.
+func (d *DockerClient) Inspect(ctx context.Context, ref string) (Image, error) {
+	inspect, _, err := d.cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return Image{}, fmt.Errorf("containertools: inspecting %s: %v", ref, err)
+	}
+
+	digest := ref
+	if len(inspect.RepoDigests) > 0 {
+		digest = inspect.RepoDigests[0]
+	}
+
+	var labels map[string]string
+	if inspect.Config != nil {
+		labels = inspect.Config.Labels
+	}
+	return Image{Digest: digest, Labels: labels}, nil
+}
+
+// tarDir archives dir into the tar stream the Docker Engine API's build
+// endpoint expects as its build context.
+func tarDir(dir string) (io.Reader, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			hdr, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			hdr.Name = filepath.ToSlash(rel)
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
@@ -0,0 +1,48 @@
+package containertools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// encodeAuth base64-encodes auth the way the Docker Engine API's
+// X-Registry-Auth header requires.
+func encodeAuth(auth types.AuthConfig) (string, error) {
+	if auth == (types.AuthConfig{}) {
+		return "", nil
+	}
+	b, err := json.Marshal(auth)
+	if err != nil {
+		return "", fmt.Errorf("containertools: encoding registry auth: %v", err)
+	}
+	return base64.URLEncoding.EncodeToString(b), nil
+}
+
+// exportImageFS creates a throwaway container from ref, exports its
+// filesystem as a tar stream, and untars it into downloadPath, so the
+// result looks the same as `docker create` + `docker export` + untar.
+func exportImageFS(ctx context.Context, cli *client.Client, ref, downloadPath string) error {
+	created, err := cli.ContainerCreate(ctx, &container.Config{Image: ref}, nil, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("containertools: creating container from %s: %v", ref, err)
+	}
+	defer cli.ContainerRemove(ctx, created.ID, types.ContainerRemoveOptions{Force: true})
+
+	rc, _, err := cli.CopyFromContainer(ctx, created.ID, "/")
+	if err != nil {
+		return fmt.Errorf("containertools: exporting %s: %v", ref, err)
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(downloadPath, 0755); err != nil {
+		return err
+	}
+	return untar(rc, downloadPath)
+}
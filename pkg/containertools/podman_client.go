@@ -0,0 +1,93 @@
+package containertools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containers/podman/v4/pkg/bindings"
+	"github.com/containers/podman/v4/pkg/bindings/images"
+	"github.com/containers/podman/v4/pkg/bindings/system"
+	"github.com/containers/podman/v4/pkg/domain/entities"
+)
+
+// PodmanClient implements Client against a podman socket via
+// github.com/containers/podman/v4/pkg/bindings.
+type PodmanClient struct {
+	// conn carries the bindings connection; every bindings call takes it
+	// in place of a client value.
+	conn context.Context
+}
+
+// NewPodmanClient connects to the podman socket named by the
+// CONTAINER_HOST environment variable, falling back to the current user's
+// default rootless socket when unset.
+func NewPodmanClient() (*PodmanClient, error) {
+	conn, err := bindings.NewConnection(context.Background(), podmanSocket())
+	if err != nil {
+		return nil, fmt.Errorf("containertools: connecting to podman: %v", err)
+	}
+	return &PodmanClient{conn: conn}, nil
+}
+
+func podmanSocket() string {
+	if v := os.Getenv("CONTAINER_HOST"); v != "" {
+		return v
+	}
+	return fmt.Sprintf("unix:///run/user/%d/podman/podman.sock", os.Getuid())
+}
+
+// Login authenticates against registry so Push/Pull can use it.
+func (p *PodmanClient) Login(ctx context.Context, registry, username, password string) error {
+	if err := system.Login(p.conn, nil, &system.LoginOptions{
+		Username: &username,
+		Password: &password,
+		Registry: &registry,
+	}); err != nil {
+		return fmt.Errorf("containertools: logging into %s: %v", registry, err)
+	}
+	return nil
+}
+
+// Build builds dockerfile (relative to dir) and tags the result tag,
+// streaming buildah's report to progress.
+func (p *PodmanClient) Build(ctx context.Context, dir, dockerfile, tag string, progress io.Writer) error {
+	report, err := images.Build(p.conn, []string{dockerfile}, entities.BuildOptions{
+		BuildOptions: imageBuildahOptions(dir, tag, progress),
+	})
+	if err != nil {
+		return fmt.Errorf("containertools: building %s: %v", tag, err)
+	}
+	fmt.Fprintf(progress, "built %s (id %s)\n", tag, report.ID)
+	return nil
+}
+
+// Push pushes tag, streaming progress to progress.
+func (p *PodmanClient) Push(ctx context.Context, tag string, progress io.Writer) error {
+	if err := images.Push(p.conn, tag, tag, &images.PushOptions{Writer: &progress}); err != nil {
+		return fmt.Errorf("containertools: pushing %s: %v", tag, err)
+	}
+	return nil
+}
+
+// Pull fetches ref and, when downloadPath is non-empty, exports its
+// filesystem there via `podman export`-equivalent bindings.
+func (p *PodmanClient) Pull(ctx context.Context, ref, downloadPath string) error {
+	if _, err := images.Pull(p.conn, ref, &images.PullOptions{}); err != nil {
+		return fmt.Errorf("containertools: pulling %s: %v", ref, err)
+	}
+	if downloadPath == "" {
+		return nil
+	}
+	return exportPodmanImageFS(p.conn, ref, downloadPath)
+}
+
+// Inspect returns ref's digest and config labels.
+func (p *PodmanClient) Inspect(ctx context.Context, ref string) (Image, error) {
+	data, err := images.GetImage(p.conn, ref, &images.GetOptions{})
+	if err != nil {
+		return Image{}, fmt.Errorf("containertools: inspecting %s: %v", ref, err)
+	}
+	return Image{Digest: data.Digest.String(), Labels: data.Labels}, nil
+}
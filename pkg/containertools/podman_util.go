@@ -0,0 +1,44 @@
+package containertools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containers/buildah/define"
+	"github.com/containers/podman/v4/pkg/bindings/containers"
+	"github.com/containers/podman/v4/pkg/specgen"
+)
+
+// imageBuildahOptions translates a build directory, tag and progress
+// writer into the buildah-flavored options podman's image build binding
+// expects.
+func imageBuildahOptions(dir, tag string, progress io.Writer) define.BuildOptions {
+	return define.BuildOptions{
+		ContextDirectory: dir,
+		Output:           tag,
+		ReportWriter:     progress,
+	}
+}
+
+// exportPodmanImageFS creates a throwaway container from ref, exports its
+// filesystem as a tar stream, and untars it into downloadPath.
+func exportPodmanImageFS(conn context.Context, ref, downloadPath string) error {
+	created, err := containers.CreateWithSpec(conn, specgen.NewSpecGenerator(ref, false), nil)
+	if err != nil {
+		return fmt.Errorf("containertools: creating container from %s: %v", ref, err)
+	}
+
+	if err := os.MkdirAll(downloadPath, 0755); err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(containers.Export(conn, created.ID, pw, nil))
+	}()
+	defer containers.Remove(conn, created.ID, nil)
+
+	return untar(pr, downloadPath)
+}
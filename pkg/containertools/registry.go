@@ -0,0 +1,292 @@
+package containertools
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// RegistryClient implements Client by talking to an OCI registry directly
+// via github.com/google/go-containerregistry, without requiring a docker or
+// podman daemon. It backs the NoneTool ContainerTool.
+type RegistryClient struct {
+	mu    sync.Mutex
+	built map[string]v1.Image
+}
+
+// NewRegistryClient returns a daemonless Client.
+func NewRegistryClient() *RegistryClient {
+	return &RegistryClient{built: map[string]v1.Image{}}
+}
+
+// Login is a no-op: auth for Push/Pull comes from authn.DefaultKeychain,
+// which already reads `docker login`'s config.json. It exists to satisfy
+// the Client interface.
+func (r *RegistryClient) Login(ctx context.Context, registry, username, password string) error {
+	return nil
+}
+
+// Build tars dir into a single image layer, stamps it with the LABEL
+// instructions found in dockerfile, and holds the result in memory under
+// tag until Push is called.
+func (r *RegistryClient) Build(ctx context.Context, dir, dockerfile, tag string, progress io.Writer) error {
+	layer, err := tarLayer(dir, dockerfile)
+	if err != nil {
+		return fmt.Errorf("containertools: building layer from %s: %v", dir, err)
+	}
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{Layer: layer})
+	if err != nil {
+		return fmt.Errorf("containertools: appending layer: %v", err)
+	}
+
+	labels, err := dockerfileLabels(filepath.Join(dir, dockerfile))
+	if err != nil {
+		return fmt.Errorf("containertools: reading labels from %s (callers must generate this Dockerfile before calling Build): %v", dockerfile, err)
+	}
+
+	img, err = mutate.Config(img, v1.Config{Labels: labels})
+	if err != nil {
+		return fmt.Errorf("containertools: setting labels: %v", err)
+	}
+
+	r.mu.Lock()
+	r.built[tag] = img
+	r.mu.Unlock()
+
+	fmt.Fprintf(progress, "built %s (%d label(s))\n", tag, len(labels))
+	return nil
+}
+
+// Push pushes the image previously built (or pulled) under tag.
+func (r *RegistryClient) Push(ctx context.Context, tag string, progress io.Writer) error {
+	r.mu.Lock()
+	img, ok := r.built[tag]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("containertools: %s was never built", tag)
+	}
+
+	ref, err := name.ParseReference(tag)
+	if err != nil {
+		return fmt.Errorf("containertools: parsing reference %s: %v", tag, err)
+	}
+
+	if err := remote.Write(ref, img, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithContext(ctx)); err != nil {
+		return fmt.Errorf("containertools: pushing %s: %v", tag, err)
+	}
+	fmt.Fprintf(progress, "pushed %s\n", tag)
+	return nil
+}
+
+// Pull fetches ref and, when downloadPath is non-empty, untars every layer
+// into it in order, so the resulting directory looks the same as it would
+// if a daemon had pulled and exported the image's filesystem.
+func (r *RegistryClient) Pull(ctx context.Context, ref, downloadPath string) error {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return fmt.Errorf("containertools: parsing reference %s: %v", ref, err)
+	}
+
+	img, err := remote.Image(parsed, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("containertools: pulling %s: %v", ref, err)
+	}
+
+	r.mu.Lock()
+	r.built[ref] = img
+	r.mu.Unlock()
+
+	if downloadPath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(downloadPath, 0755); err != nil {
+		return err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return fmt.Errorf("containertools: reading layers of %s: %v", ref, err)
+	}
+
+	for _, layer := range layers {
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			return fmt.Errorf("containertools: reading layer of %s: %v", ref, err)
+		}
+		err = untar(rc, downloadPath)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("containertools: extracting layer of %s: %v", ref, err)
+		}
+	}
+	return nil
+}
+
+// Inspect resolves ref's digest and config labels without fetching its
+// layers.
+func (r *RegistryClient) Inspect(ctx context.Context, ref string) (Image, error) {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return Image{}, fmt.Errorf("containertools: parsing reference %s: %v", ref, err)
+	}
+
+	img, err := remote.Image(parsed, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithContext(ctx))
+	if err != nil {
+		return Image{}, fmt.Errorf("containertools: inspecting %s: %v", ref, err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return Image{}, fmt.Errorf("containertools: resolving digest of %s: %v", ref, err)
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return Image{}, fmt.Errorf("containertools: reading config of %s: %v", ref, err)
+	}
+
+	return Image{Digest: digest.String(), Labels: cfg.Config.Labels}, nil
+}
+
+// dockerfileLabels extracts the key/value pairs from every `LABEL`
+// instruction in dockerfile, in the `LABEL key=value ...` form opm's
+// generated Dockerfiles use.
+func dockerfileLabels(dockerfile string) (map[string]string, error) {
+	f, err := os.Open(dockerfile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	labels := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "LABEL ") {
+			continue
+		}
+		for _, pair := range strings.Fields(strings.TrimPrefix(line, "LABEL ")) {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			labels[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return labels, scanner.Err()
+}
+
+// tarLayer builds an uncompressed tar layer out of every regular file under
+// dir, in lexical path order, preserving its relative paths. Each entry
+// keeps the file's mode, ownership and modification time, so the layer's
+// digest changes when those do. dockerfile itself
+// is skipped: a real docker/podman build doesn't copy the Dockerfile into
+// the resulting image, and the "none" backend should match that.
+func tarLayer(dir, dockerfile string) (v1.Layer, error) {
+	skip := filepath.ToSlash(dockerfile)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if filepath.ToSlash(rel) == skip {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	b := buf.Bytes()
+	return tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	})
+}
+
+// untar extracts r into dir, creating any intermediate directories needed.
+// Entries whose name would land outside dir (e.g. via a "../" path
+// traversal) are rejected rather than written.
+func untar(r io.Reader, dir string) error {
+	cleanDir := filepath.Clean(dir)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, hdr.Name)
+		if target != cleanDir && !strings.HasPrefix(target, cleanDir+string(os.PathSeparator)) {
+			return fmt.Errorf("containertools: tar entry %q escapes %s", hdr.Name, dir)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode))
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package bundle
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// CopyOption customizes CopyFunc beyond its required arguments.
+type CopyOption func(*copyOptions)
+
+type copyOptions struct {
+	toTag string
+}
+
+// WithToTag rewrites the destination image's tag instead of reusing src's
+// tag.
+func WithToTag(tag string) CopyOption {
+	return func(o *copyOptions) {
+		o.toTag = tag
+	}
+}
+
+// CopyFunc copies a single bundle image from src to dst, preserving its
+// digest rather than rebuilding it.
+func CopyFunc(src, dst string, opts ...CopyOption) error {
+	cfg := &copyOptions{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	dstRef := dst
+	if cfg.toTag != "" {
+		dstRef = dst + ":" + cfg.toTag
+	} else if srcTag, err := name.NewTag(src, name.StrictValidation); err == nil {
+		// An untagged dst takes src's tag; a dst with its own tag is used as given.
+		if _, err := name.NewRepository(dst); err == nil {
+			dstRef = dst + ":" + srcTag.TagStr()
+		}
+	}
+
+	if err := crane.Copy(src, dstRef); err != nil {
+		return fmt.Errorf("bundle: copying %s to %s: %v", src, dstRef, err)
+	}
+	return nil
+}
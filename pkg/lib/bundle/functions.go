@@ -0,0 +1,77 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/lib/signature"
+)
+
+// Option customizes BuildFunc's behavior beyond its required arguments.
+type Option func(*buildOptions)
+
+type buildOptions struct {
+	signer   signature.Signer
+	progress io.Writer
+}
+
+// WithSigner makes BuildFunc sign the bundle image with signer once it has
+// been pushed. Signing is skipped entirely when no signer is given.
+func WithSigner(signer signature.Signer) Option {
+	return func(o *buildOptions) {
+		o.signer = signer
+	}
+}
+
+// WithProgress streams the container client's build and push output to w
+// instead of discarding it.
+func WithProgress(w io.Writer) Option {
+	return func(o *buildOptions) {
+		o.progress = w
+	}
+}
+
+// Bundle image labels recognized by opm and OLM when unpacking a bundle.
+const (
+	labelMediaType      = "operators.operatorframework.io.bundle.mediatype.v1"
+	labelManifestsDir   = "operators.operatorframework.io.bundle.manifests.v1"
+	labelMetadataDir    = "operators.operatorframework.io.bundle.metadata.v1"
+	labelPackage        = "operators.operatorframework.io.bundle.package.v1"
+	labelChannels       = "operators.operatorframework.io.bundle.channels.v1"
+	labelChannelDefault = "operators.operatorframework.io.bundle.channel.default.v1"
+)
+
+// BuildFunc generates bundle metadata in directory (unless it is already
+// present) and builds+pushes a bundle image tagged imageTag from it using
+// client.
+func BuildFunc(ctx context.Context, directory, outDockerfile, imageTag string, client containertools.Client, packageName, channels, channelDefault string, overwrite bool, opts ...Option) error {
+	cfg := &buildOptions{progress: io.Discard}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	dockerfile := outDockerfile
+	if dockerfile == "" {
+		dockerfile = "bundle.Dockerfile"
+	}
+
+	if err := GenerateFunc(directory, dockerfile, packageName, channels, channelDefault, overwrite); err != nil {
+		return fmt.Errorf("bundle: generating metadata: %v", err)
+	}
+
+	if err := client.Build(ctx, directory, dockerfile, imageTag, cfg.progress); err != nil {
+		return fmt.Errorf("bundle: building %s: %v", imageTag, err)
+	}
+	if err := client.Push(ctx, imageTag, cfg.progress); err != nil {
+		return fmt.Errorf("bundle: pushing %s: %v", imageTag, err)
+	}
+
+	if cfg.signer != nil {
+		if _, err := cfg.signer.Sign(ctx, imageTag); err != nil {
+			return fmt.Errorf("bundle: signing %s: %v", imageTag, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package bundle
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// GenerateFunc writes the bundle.Dockerfile and metadata/annotations.yaml
+// needed to build directory into a bundle image, unless they already exist
+// and overwrite is false.
+func GenerateFunc(directory, outDockerfile, packageName, channels, channelDefault string, overwrite bool) error {
+	metadataDir := filepath.Join(directory, "metadata")
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		return err
+	}
+
+	annotationsPath := filepath.Join(metadataDir, "annotations.yaml")
+	dockerfilePath := filepath.Join(directory, outDockerfile)
+	if !overwrite {
+		_, annotationsErr := os.Stat(annotationsPath)
+		_, dockerfileErr := os.Stat(dockerfilePath)
+		if annotationsErr == nil && dockerfileErr == nil {
+			return nil
+		}
+	}
+
+	annotations := fmt.Sprintf(`annotations:
+  %s: registry+v1
+  %s: manifests/
+  %s: metadata/
+  %s: %s
+  %s: %s
+  %s: %s
+`, labelMediaType, labelManifestsDir, labelMetadataDir, labelPackage, packageName, labelChannels, channels, labelChannelDefault, channelDefault)
+
+	if err := ioutil.WriteFile(annotationsPath, []byte(annotations), 0644); err != nil {
+		return err
+	}
+
+	dockerfile := fmt.Sprintf(`FROM scratch
+
+LABEL %s=registry+v1
+LABEL %s=manifests/
+LABEL %s=metadata/
+LABEL %s=%s
+LABEL %s=%s
+LABEL %s=%s
+
+COPY manifests /manifests/
+COPY metadata /metadata/
+`, labelMediaType, labelManifestsDir, labelMetadataDir, labelPackage, packageName, labelChannels, channels, labelChannelDefault, channelDefault)
+
+	return ioutil.WriteFile(dockerfilePath, []byte(dockerfile), 0644)
+}
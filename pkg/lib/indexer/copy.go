@@ -0,0 +1,190 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+)
+
+// CopyRequest describes an index mirroring operation.
+type CopyRequest struct {
+	From string
+	To   string
+	// ToTag, when set, overrides the tag every copied image (index and
+	// bundles alike) is given in the destination registry. When empty,
+	// each image keeps its source tag.
+	ToTag string
+	// OnlyPackages restricts the copy to these packages. A nil/empty
+	// slice copies every package in the index.
+	OnlyPackages []string
+	// DryRun makes CopyIndex return the plan it would execute without
+	// copying anything.
+	DryRun bool
+}
+
+// CopyEntry is a single source-to-destination image copy.
+type CopyEntry struct {
+	Package string
+	From    string
+	To      string
+}
+
+// CopyPlan is the set of content-addressed copies CopyIndex performed, or,
+// for a dry run, would have performed.
+type CopyPlan struct {
+	Index   CopyEntry
+	Bundles []CopyEntry
+}
+
+// IndexCopier mirrors an index image and every bundle image it references
+// from one registry to another, preserving digests.
+type IndexCopier struct {
+	client containertools.Client
+	logger *logrus.Entry
+}
+
+// NewIndexCopier returns an IndexCopier that uses client to pull the source
+// index image's embedded database.
+func NewIndexCopier(client containertools.Client, logger *logrus.Entry) *IndexCopier {
+	return &IndexCopier{
+		client: client,
+		logger: logger,
+	}
+}
+
+// CopyIndex plans, and unless request.DryRun performs, copying
+// request.From and every bundle image it references to request.To.
+func (c *IndexCopier) CopyIndex(ctx context.Context, request CopyRequest) (*CopyPlan, error) {
+	downloadPath, err := os.MkdirTemp("", "opm-copy-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(downloadPath)
+
+	if err := c.client.Pull(ctx, request.From, downloadPath); err != nil {
+		return nil, fmt.Errorf("indexer: pulling index %s: %v", request.From, err)
+	}
+
+	images, err := listBundleImages(ctx, downloadPath, request.OnlyPackages)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: enumerating bundle images: %v", err)
+	}
+
+	plan := &CopyPlan{
+		Index: CopyEntry{From: request.From, To: destRef(request.To, request.ToTag, request.From)},
+	}
+	for _, img := range images {
+		plan.Bundles = append(plan.Bundles, CopyEntry{
+			Package: img.Package,
+			From:    img.Image,
+			To:      destBundleRef(request.To, request.ToTag, img.Image),
+		})
+	}
+
+	if request.DryRun {
+		return plan, nil
+	}
+
+	for _, entry := range plan.Bundles {
+		c.logger.Infof("copying bundle %s -> %s", entry.From, entry.To)
+		if err := bundle.CopyFunc(entry.From, entry.To); err != nil {
+			return plan, err
+		}
+	}
+
+	c.logger.Infof("copying index %s -> %s", plan.Index.From, plan.Index.To)
+	if err := crane.Copy(plan.Index.From, plan.Index.To); err != nil {
+		return plan, fmt.Errorf("indexer: copying index %s to %s: %v", plan.Index.From, plan.Index.To, err)
+	}
+
+	return plan, nil
+}
+
+// destRef rewrites src's reference to live under the to repository,
+// optionally overriding its tag with toTag. It is used for the index image
+// itself, which always lands at exactly the requested destination
+// repository.
+func destRef(to, toTag, src string) string {
+	_, tag := splitRef(src)
+	if toTag != "" {
+		tag = toTag
+	}
+	return to + ":" + tag
+}
+
+// destBundleRef rewrites src (a bundle image reference) to live under the
+// same registry host and namespace as to, but keeping src's own repository
+// name rather than to's, optionally overriding its tag with toTag. Bundle
+// images, unlike the index image, keep their own repository name so that
+// bundles pulled from different source repositories don't collide at the
+// destination; they still honor any namespace/project segment the caller
+// put in to (e.g. "myregistry.io/myorg/myindex" copies bundles under
+// "myregistry.io/myorg/<bundle-repo-name>").
+func destBundleRef(to, toTag, src string) string {
+	toRepo, _ := splitRef(to)
+	host, toPath := splitHostPath(toRepo)
+	namespace := namespaceOf(toPath)
+
+	srcRepo, tag := splitRef(src)
+	if toTag != "" {
+		tag = toTag
+	}
+	_, srcPath := splitHostPath(srcRepo)
+	name := nameOf(srcPath)
+
+	dest := host
+	if namespace != "" {
+		dest += "/" + namespace
+	}
+	return dest + "/" + name + ":" + tag
+}
+
+// splitHostPath splits repo into its registry host (and optional port) and
+// the remaining repository path.
+func splitHostPath(repo string) (host, path string) {
+	idx := strings.Index(repo, "/")
+	if idx == -1 {
+		return repo, ""
+	}
+	return repo[:idx], repo[idx+1:]
+}
+
+// namespaceOf returns every segment of path except the last, which is
+// presumed to be the repository's own name (e.g. an index's name).
+func namespaceOf(path string) string {
+	idx := strings.LastIndex(path, "/")
+	if idx == -1 {
+		return ""
+	}
+	return path[:idx]
+}
+
+// nameOf returns the last segment of path, its repository name.
+func nameOf(path string) string {
+	idx := strings.LastIndex(path, "/")
+	if idx == -1 {
+		return path
+	}
+	return path[idx+1:]
+}
+
+// splitRef splits a reference of the form repo[:tag] into its repo and tag,
+// defaulting the tag to "latest" when none is present. The final path
+// segment is searched for a colon so a registry host:port isn't mistaken
+// for a tag separator.
+func splitRef(ref string) (repo, tag string) {
+	lastSlash := strings.LastIndex(ref, "/")
+	colon := strings.LastIndex(ref[lastSlash+1:], ":")
+	if colon == -1 {
+		return ref, "latest"
+	}
+	colon += lastSlash + 1
+	return ref[:colon], ref[colon+1:]
+}
@@ -0,0 +1,165 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/lib/signature"
+	"github.com/operator-framework/operator-registry/pkg/sqlite"
+)
+
+// indexDBFile is the well-known location of the index's sqlite database
+// inside both the build context and an exported/pulled index image.
+const indexDBFile = "index.db"
+
+// buildIndexDB assembles a sqlite index database seeded with fromIndex (if
+// any) plus bundles, and returns the directory it was written to so callers
+// can hand that directory to a container build. When signer is non-nil,
+// each bundle is signed and its digest recorded in the operatorbundle
+// table's signature_digest column.
+func buildIndexDB(ctx context.Context, client containertools.Client, fromIndex string, bundles []string, permissive bool, signer signature.Signer) (string, error) {
+	dir, err := os.MkdirTemp("", "opm-index-")
+	if err != nil {
+		return "", err
+	}
+
+	if fromIndex != "" {
+		if err := client.Pull(ctx, fromIndex, dir); err != nil {
+			return "", fmt.Errorf("pulling from-index %s: %v", fromIndex, err)
+		}
+	}
+
+	dbPath := filepath.Join(dir, indexDBFile)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	dbLoader, err := sqlite.NewSQLLiteLoader(db)
+	if err != nil {
+		return "", err
+	}
+	if err := dbLoader.Migrate(ctx); err != nil {
+		return "", err
+	}
+
+	populator := sqlite.NewSQLLoaderForBundles(dbLoader, bundles, permissive)
+	if err := populator.Populate(); err != nil {
+		return "", fmt.Errorf("populating index database: %v", err)
+	}
+
+	if signer != nil {
+		for _, bundleImage := range bundles {
+			digest, err := signer.Sign(ctx, bundleImage)
+			if err != nil {
+				return "", fmt.Errorf("signing bundle %s: %v", bundleImage, err)
+			}
+			if err := sqlite.SetBundleSignatureDigest(db, bundleImage, digest); err != nil {
+				return "", fmt.Errorf("recording signature digest for %s: %v", bundleImage, err)
+			}
+		}
+	}
+
+	return dir, nil
+}
+
+// exportPackageFromDB reads the index.db found under downloadPath and
+// writes out the file-based manifests for pkg alongside it.
+func exportPackageFromDB(downloadPath, pkg string) error {
+	dbPath := filepath.Join(downloadPath, indexDBFile)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	querier, err := sqlite.NewSQLLiteQuerierFromDb(db)
+	if err != nil {
+		return err
+	}
+
+	return sqlite.NewSQLPackageExporter(querier).Export(pkg, downloadPath)
+}
+
+// verifyPackageSignatures checks every bundle image referenced by pkg in
+// the index.db found under downloadPath against policy, failing closed on
+// the first bundle whose signature doesn't verify.
+func verifyPackageSignatures(ctx context.Context, downloadPath, pkg string, verifier signature.Signer, policy signature.Policy) error {
+	dbPath := filepath.Join(downloadPath, indexDBFile)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	querier, err := sqlite.NewSQLLiteQuerierFromDb(db)
+	if err != nil {
+		return err
+	}
+
+	images, err := querier.ListBundleImagesForPackage(ctx, pkg)
+	if err != nil {
+		return fmt.Errorf("listing bundle images for %s: %v", pkg, err)
+	}
+
+	for _, image := range images {
+		if err := verifier.Verify(ctx, image, policy); err != nil {
+			return fmt.Errorf("bundle %s: %v", image, err)
+		}
+	}
+	return nil
+}
+
+// packageImage pairs a bundle image reference with the package it belongs
+// to.
+type packageImage struct {
+	Package string
+	Image   string
+}
+
+// listBundleImages enumerates every bundle image referenced by the index.db
+// found under downloadPath, across every package (or just those named in
+// onlyPackages, when non-empty).
+func listBundleImages(ctx context.Context, downloadPath string, onlyPackages []string) ([]packageImage, error) {
+	dbPath := filepath.Join(downloadPath, indexDBFile)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	querier, err := sqlite.NewSQLLiteQuerierFromDb(db)
+	if err != nil {
+		return nil, err
+	}
+
+	packages, err := querier.ListPackages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing packages: %v", err)
+	}
+
+	only := make(map[string]bool, len(onlyPackages))
+	for _, p := range onlyPackages {
+		only[p] = true
+	}
+
+	var images []packageImage
+	for _, pkg := range packages {
+		if len(only) > 0 && !only[pkg] {
+			continue
+		}
+		refs, err := querier.ListBundleImagesForPackage(ctx, pkg)
+		if err != nil {
+			return nil, fmt.Errorf("listing bundle images for %s: %v", pkg, err)
+		}
+		for _, ref := range refs {
+			images = append(images, packageImage{Package: pkg, Image: ref})
+		}
+	}
+	return images, nil
+}
@@ -0,0 +1,31 @@
+package indexer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// indexLabelDatabase is the label opm and OLM use to locate the sqlite
+// database inside an index image.
+const indexLabelDatabase = "operators.operatorframework.io.index.database.v1"
+
+// generateIndexDockerfile writes the Dockerfile needed to build dir (which
+// already contains index.db) into an index image, unless dockerfile is
+// already present there.
+func generateIndexDockerfile(dir, dockerfile string) error {
+	dockerfilePath := filepath.Join(dir, dockerfile)
+	if _, err := os.Stat(dockerfilePath); err == nil {
+		return nil
+	}
+
+	contents := fmt.Sprintf(`FROM scratch
+
+LABEL %s=./%s
+
+COPY %s /%s
+`, indexLabelDatabase, indexDBFile, indexDBFile, indexDBFile)
+
+	return ioutil.WriteFile(dockerfilePath, []byte(contents), 0644)
+}
@@ -0,0 +1,159 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	"github.com/operator-framework/operator-registry/pkg/lib/signature"
+)
+
+// AddToIndexRequest describes an index build/update.
+type AddToIndexRequest struct {
+	Generate          bool
+	FromIndex         string
+	BinarySourceImage string
+	OutDockerfile     string
+	Tag               string
+	Bundles           []string
+	Permissive        bool
+}
+
+// IndexAdder builds (or rebuilds, if FromIndex is set) an index image
+// containing the given bundles, using a containertools.Client.
+type IndexAdder struct {
+	client   containertools.Client
+	logger   *logrus.Entry
+	signer   signature.Signer
+	progress io.Writer
+}
+
+// IndexAdderOption customizes an IndexAdder beyond its required arguments.
+type IndexAdderOption func(*IndexAdder)
+
+// WithSigner makes the IndexAdder sign each bundle image as it's added to
+// the index, and sign the resulting index image once it's pushed. Signing
+// is skipped entirely when no signer is given.
+func WithSigner(signer signature.Signer) IndexAdderOption {
+	return func(a *IndexAdder) {
+		a.signer = signer
+	}
+}
+
+// WithAddProgress streams the client's build and push output to w instead
+// of discarding it.
+func WithAddProgress(w io.Writer) IndexAdderOption {
+	return func(a *IndexAdder) {
+		a.progress = w
+	}
+}
+
+// NewIndexAdder returns an IndexAdder that uses client to build and push
+// the resulting index image.
+func NewIndexAdder(client containertools.Client, logger *logrus.Entry, opts ...IndexAdderOption) *IndexAdder {
+	a := &IndexAdder{
+		client:   client,
+		logger:   logger,
+		progress: io.Discard,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// AddToIndex builds an index image per request and pushes it to request.Tag.
+func (a *IndexAdder) AddToIndex(ctx context.Context, request AddToIndexRequest) error {
+	a.logger.Infof("adding %d bundle(s) to index %s", len(request.Bundles), request.Tag)
+
+	dbPath, err := buildIndexDB(ctx, a.client, request.FromIndex, request.Bundles, request.Permissive, a.signer)
+	if err != nil {
+		return fmt.Errorf("indexer: building index database: %v", err)
+	}
+
+	dockerfile := request.OutDockerfile
+	if dockerfile == "" {
+		dockerfile = "index.Dockerfile"
+	}
+	if err := generateIndexDockerfile(dbPath, dockerfile); err != nil {
+		return fmt.Errorf("indexer: generating %s: %v", dockerfile, err)
+	}
+
+	if err := a.client.Build(ctx, dbPath, dockerfile, request.Tag, a.progress); err != nil {
+		return fmt.Errorf("indexer: building index %s: %v", request.Tag, err)
+	}
+	if err := a.client.Push(ctx, request.Tag, a.progress); err != nil {
+		return fmt.Errorf("indexer: pushing index %s: %v", request.Tag, err)
+	}
+
+	if a.signer != nil {
+		if _, err := a.signer.Sign(ctx, request.Tag); err != nil {
+			return fmt.Errorf("indexer: signing index %s: %v", request.Tag, err)
+		}
+	}
+	return nil
+}
+
+// ExportFromIndexRequest describes what to export from an index image, and
+// where to put it.
+type ExportFromIndexRequest struct {
+	Index        string
+	Package      string
+	DownloadPath string
+}
+
+// IndexExporter pulls an index image and exports one package's bundles from
+// it to a local directory, using a containertools.Client.
+type IndexExporter struct {
+	client   containertools.Client
+	logger   *logrus.Entry
+	verifier signature.Signer
+	policy   signature.Policy
+}
+
+// IndexExporterOption customizes an IndexExporter beyond its required
+// arguments.
+type IndexExporterOption func(*IndexExporter)
+
+// WithVerification makes the IndexExporter reject any bundle in the
+// exported package whose image signature does not satisfy policy.
+func WithVerification(verifier signature.Signer, policy signature.Policy) IndexExporterOption {
+	return func(e *IndexExporter) {
+		e.verifier = verifier
+		e.policy = policy
+	}
+}
+
+// NewIndexExporter returns an IndexExporter that uses client to pull the
+// index image.
+func NewIndexExporter(client containertools.Client, logger *logrus.Entry, opts ...IndexExporterOption) *IndexExporter {
+	e := &IndexExporter{
+		client: client,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// ExportFromIndex pulls request.Index and writes out the manifests for
+// request.Package under request.DownloadPath.
+func (e *IndexExporter) ExportFromIndex(ctx context.Context, request ExportFromIndexRequest) error {
+	e.logger.Infof("exporting package %s from index %s", request.Package, request.Index)
+
+	if err := e.client.Pull(ctx, request.Index, request.DownloadPath); err != nil {
+		return fmt.Errorf("indexer: pulling index %s: %v", request.Index, err)
+	}
+
+	if e.verifier != nil {
+		if err := verifyPackageSignatures(ctx, request.DownloadPath, request.Package, e.verifier, e.policy); err != nil {
+			return fmt.Errorf("indexer: verifying package %s: %v", request.Package, err)
+		}
+	}
+
+	return exportPackageFromDB(request.DownloadPath, request.Package)
+}
@@ -0,0 +1,101 @@
+// Package signature adds opt-in cosign signing and verification of bundle
+// and index images on top of pkg/containertools and pkg/lib/indexer.
+package signature
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
+	"github.com/sigstore/cosign/cmd/cosign/cli/verify"
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sirupsen/logrus"
+)
+
+// Signer signs and verifies the manifest digest of bundle and index images.
+type Signer interface {
+	// Sign signs imageRef, attaching the signature under the registry's
+	// conventional sha256-<digest>.sig tag, and returns the digest that
+	// was signed.
+	Sign(ctx context.Context, imageRef string) (digest string, err error)
+	// Verify checks that imageRef carries a signature satisfying policy,
+	// returning an error if it does not.
+	Verify(ctx context.Context, imageRef string, policy Policy) error
+}
+
+// Policy constrains which signatures Verify accepts.
+type Policy struct {
+	// KeyRef, when set, requires the signature to have been made with
+	// this public key file or KMS URI. When empty, keyless verification
+	// against the public Fulcio CA is used instead.
+	KeyRef string
+	// RequireRekor requires the signature to carry a Rekor transparency
+	// log entry.
+	RequireRekor bool
+	// Identities restricts keyless verification to these accepted OIDC
+	// identities. Ignored when KeyRef is set.
+	Identities []cosign.Identity
+}
+
+// CosignSigner signs and verifies images using sigstore/cosign.
+type CosignSigner struct {
+	// KeyRef is the private key file or KMS URI to sign with. Empty
+	// means keyless (OIDC) signing against the public Fulcio/Rekor
+	// instances.
+	KeyRef string
+
+	logger *logrus.Entry
+}
+
+// NewCosignSigner returns a CosignSigner. keyRef may be empty to sign
+// keylessly.
+func NewCosignSigner(keyRef string, logger *logrus.Entry) *CosignSigner {
+	return &CosignSigner{KeyRef: keyRef, logger: logger}
+}
+
+// Sign signs imageRef's manifest digest and pushes the signature to the
+// registry under its sha256-<digest>.sig tag.
+func (s *CosignSigner) Sign(ctx context.Context, imageRef string) (string, error) {
+	ko := options.KeyOpts{KeyRef: s.KeyRef}
+
+	digest, err := craneDigest(ctx, imageRef)
+	if err != nil {
+		return "", fmt.Errorf("signature: resolving digest of %s: %v", imageRef, err)
+	}
+
+	if err := sign.SignCmd(&options.RootOptions{Timeout: options.DefaultTimeout}, ko, options.SignOptions{
+		Upload:     true,
+		Recursive:  false,
+		TlogUpload: true,
+	}, []string{imageRef}); err != nil {
+		return "", fmt.Errorf("signature: signing %s: %v", imageRef, err)
+	}
+
+	s.logger.Infof("signed %s (%s)", imageRef, digest)
+	return digest, nil
+}
+
+// Verify checks imageRef's signature against policy.
+func (s *CosignSigner) Verify(ctx context.Context, imageRef string, policy Policy) error {
+	ko := options.KeyOpts{KeyRef: policy.KeyRef}
+
+	cmd := &verify.VerifyCommand{
+		KeyOpts:    ko,
+		RekorURL:   options.DefaultRekorURL,
+		IgnoreTlog: !policy.RequireRekor,
+		Identities: policy.Identities,
+	}
+
+	if err := cmd.Exec(ctx, []string{imageRef}); err != nil {
+		return fmt.Errorf("signature: verification failed for %s: %v", imageRef, err)
+	}
+	return nil
+}
+
+// craneDigest resolves imageRef to its canonical sha256 digest. It is an
+// unexported package variable, so only code in this package (such as its
+// tests) can stub it.
+var craneDigest = func(ctx context.Context, imageRef string) (string, error) {
+	return cosign.ResolveDigest(imageRef)
+}
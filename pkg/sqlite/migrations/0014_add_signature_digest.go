@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+const SignatureDigestMigrationKey = 14
+
+// SignatureDigestMigration adds a signature_digest column to the
+// operatorbundle table, recording the cosign-signed digest of each
+// bundle's image when the index is built with a signer. `opm alpha verify`
+// and IndexExporter.ExportFromIndex check the signatures in the registry
+// and do not read this column.
+var SignatureDigestMigration = &Migration{
+	Id: SignatureDigestMigrationKey,
+
+	Up: func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			ALTER TABLE operatorbundle ADD COLUMN signature_digest TEXT DEFAULT NULL
+		`)
+		return err
+	},
+	Down: func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			ALTER TABLE operatorbundle DROP COLUMN signature_digest
+		`)
+		return err
+	},
+}
+
+func init() {
+	registerMigration(SignatureDigestMigrationKey, SignatureDigestMigration)
+}
@@ -0,0 +1,37 @@
+// Package migrations contains the versioned sqlite schema migrations
+// applied by sqlite.SQLLiteLoader.Migrate, in ascending Id order.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+)
+
+// Migration is a single reversible schema change.
+type Migration struct {
+	Id   int
+	Up   func(ctx context.Context, tx *sql.Tx) error
+	Down func(ctx context.Context, tx *sql.Tx) error
+}
+
+var migrations = map[int]*Migration{}
+
+func registerMigration(id int, m *Migration) {
+	migrations[id] = m
+}
+
+// All returns every registered migration in ascending Id order.
+func All() []*Migration {
+	ids := make([]int, 0, len(migrations))
+	for id := range migrations {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	ordered := make([]*Migration, 0, len(ids))
+	for _, id := range ids {
+		ordered = append(ordered, migrations[id])
+	}
+	return ordered
+}
@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SetBundleSignatureDigest records digest as the signature_digest for
+// bundleImage's row in the operatorbundle table, the column added by
+// migrations.SignatureDigestMigration.
+func SetBundleSignatureDigest(db *sql.DB, bundleImage, digest string) error {
+	res, err := db.Exec(`UPDATE operatorbundle SET signature_digest = ? WHERE bundlepath = ?`, digest, bundleImage)
+	if err != nil {
+		return fmt.Errorf("updating signature digest for %s: %v", bundleImage, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no bundle row found for image %s", bundleImage)
+	}
+	return nil
+}
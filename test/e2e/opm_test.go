@@ -4,14 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"io/ioutil"
+	"net/http/httptest"
 	"os"
-	"os/exec"
 	"path/filepath"
 
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
+	"github.com/operator-framework/operator-registry/pkg/containertools"
 	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
 	"github.com/operator-framework/operator-registry/pkg/lib/indexer"
+	"github.com/operator-framework/operator-registry/pkg/lib/signature"
 	"github.com/operator-framework/operator-registry/pkg/sqlite"
 	"github.com/otiai10/copy"
 	"github.com/sirupsen/logrus"
@@ -62,14 +66,14 @@ func inTemporaryBuildContext(f func() error) (rerr error) {
 	return f()
 }
 
-func buildBundlesWith(containerTool string) error {
+func buildAndPushBundles(ctx context.Context, client containertools.Client) error {
 	for tag, path := range map[string]string{
 		bundleTag1: bundlePath1,
 		bundleTag2: bundlePath2,
 		bundleTag3: bundlePath3,
 	} {
 		if err := inTemporaryBuildContext(func() error {
-			return bundle.BuildFunc(path, "", bundleImage+":"+tag, containerTool, packageName, channels, defaultChannel, false)
+			return bundle.BuildFunc(ctx, path, "", bundleImage+":"+tag, client, packageName, channels, defaultChannel, false, bundle.WithProgress(ginkgo.GinkgoWriter))
 		}); err != nil {
 			return err
 		}
@@ -77,14 +81,14 @@ func buildBundlesWith(containerTool string) error {
 	return nil
 }
 
-func buildIndexWith(containerTool string) error {
+func buildAndPushIndex(ctx context.Context, client containertools.Client) error {
 	bundles := []string{
 		bundleImage + ":" + bundleTag1,
 		bundleImage + ":" + bundleTag2,
 		bundleImage + ":" + bundleTag3,
 	}
 	logger := logrus.WithFields(logrus.Fields{"bundles": bundles})
-	indexAdder := indexer.NewIndexAdder(containerTool, logger)
+	indexAdder := indexer.NewIndexAdder(client, logger, indexer.WithAddProgress(ginkgo.GinkgoWriter))
 
 	request := indexer.AddToIndexRequest{
 		Generate:          false,
@@ -96,39 +100,62 @@ func buildIndexWith(containerTool string) error {
 		Permissive:        false,
 	}
 
-	return indexAdder.AddToIndex(request)
+	return indexAdder.AddToIndex(ctx, request)
 }
 
-func pushWith(containerTool, image string) error {
-	dockerpush := exec.Command(containerTool, "push", image)
-	return dockerpush.Run()
+func exportWith(ctx context.Context, client containertools.Client) error {
+	logger := logrus.WithFields(logrus.Fields{"package": packageName})
+	indexExporter := indexer.NewIndexExporter(client, logger)
+
+	request := indexer.ExportFromIndexRequest{
+		Index:        indexImage,
+		Package:      packageName,
+		DownloadPath: "downloaded",
+	}
+
+	return indexExporter.ExportFromIndex(ctx, request)
 }
 
-func pushBundles(containerTool string) error {
-	err := pushWith(containerTool, bundleImage+":"+bundleTag1)
-	if err != nil {
-		return err
+// cosignKeyRef returns the key file path used to sign and verify bundles in
+// these specs, or "" if signing isn't configured for this run.
+func cosignKeyRef() string {
+	return os.Getenv("COSIGN_KEY")
+}
+
+func signBundles(signer signature.Signer) error {
+	for _, tag := range []string{bundleTag1, bundleTag2, bundleTag3} {
+		if _, err := signer.Sign(context.Background(), bundleImage+":"+tag); err != nil {
+			return err
+		}
 	}
-	err = pushWith(containerTool, bundleImage+":"+bundleTag2)
-	if err != nil {
-		return err
+	return nil
+}
+
+func stripBundleSignatures(signer signature.Signer) error {
+	for _, tag := range []string{bundleTag1, bundleTag2, bundleTag3} {
+		digest, err := signer.Sign(context.Background(), bundleImage+":"+tag)
+		if err != nil {
+			return err
+		}
+		sigTag := bundleImage + ":sha256-" + digest + ".sig"
+		if err := crane.Delete(sigTag); err != nil {
+			return err
+		}
 	}
-	err = pushWith(containerTool, bundleImage+":"+bundleTag3)
-	return err
+	return nil
 }
 
-func exportWith(containerTool string) error {
+func exportVerifiedWith(ctx context.Context, client containertools.Client, signer signature.Signer) error {
 	logger := logrus.WithFields(logrus.Fields{"package": packageName})
-	indexExporter := indexer.NewIndexExporter(containerTool, logger)
+	indexExporter := indexer.NewIndexExporter(client, logger, indexer.WithVerification(signer, signature.Policy{KeyRef: cosignKeyRef()}))
 
 	request := indexer.ExportFromIndexRequest{
-		Index:         indexImage,
-		Package:       packageName,
-		DownloadPath:  "downloaded",
-		ContainerTool: containerTool,
+		Index:        indexImage,
+		Package:      packageName,
+		DownloadPath: "downloaded-verified",
 	}
 
-	return indexExporter.ExportFromIndex(request)
+	return indexExporter.ExportFromIndex(ctx, request)
 }
 
 func initialize() error {
@@ -157,7 +184,9 @@ func initialize() error {
 }
 
 var _ = ginkgo.Describe("opm", func() {
-	IncludeSharedSpecs := func(containerTool string) {
+	IncludeSharedSpecs := func(tool containertools.ContainerTool) {
+		var client containertools.Client
+
 		ginkgo.BeforeEach(func() {
 			dockerUsername := os.Getenv("DOCKER_USERNAME")
 			dockerPassword := os.Getenv("DOCKER_PASSWORD")
@@ -166,43 +195,119 @@ var _ = ginkgo.Describe("opm", func() {
 				ginkgo.Skip("registry credentials are not available")
 			}
 
-			dockerlogin := exec.Command(containerTool, "login", "-u", dockerUsername, "-p", dockerPassword, "quay.io")
-			err := dockerlogin.Run()
+			var err error
+			client, err = containertools.NewClient(tool)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			err = client.Login(context.Background(), "quay.io", dockerUsername, dockerPassword)
 			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error logging into quay.io")
 		})
 
 		ginkgo.It("builds and manipulates bundle and index images", func() {
-			ginkgo.By("building bundles")
-			err := buildBundlesWith(containerTool)
-			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			ctx := context.Background()
 
-			ginkgo.By("pushing bundles")
-			err = pushBundles(containerTool)
+			ginkgo.By("building and pushing bundles")
+			err := buildAndPushBundles(ctx, client)
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 
-			ginkgo.By("building an index")
-			err = buildIndexWith(containerTool)
+			ginkgo.By("building and pushing an index")
+			err = buildAndPushIndex(ctx, client)
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 
-			ginkgo.By("pushing an index")
-			err = pushWith(containerTool, indexImage)
-			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			if cosignKeyRef() != "" {
+				ginkgo.By("signing and verifying bundles")
+				signer := signature.NewCosignSigner(cosignKeyRef(), logrus.WithFields(logrus.Fields{"package": packageName}))
+
+				err = signBundles(signer)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+				err = exportVerifiedWith(ctx, client, signer)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+				err = stripBundleSignatures(signer)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+				err = exportVerifiedWith(ctx, client, signer)
+				gomega.Expect(err).To(gomega.HaveOccurred(), "export should fail once bundle signatures are stripped")
+			}
 
 			ginkgo.By("exporting an index to disk")
-			err = exportWith(containerTool)
+			err = exportWith(ctx, client)
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 
 			ginkgo.By("loading manifests from a directory")
 			err = initialize()
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			ginkgo.By("mirroring index to a second registry")
+			mirrorSrv := httptest.NewServer(registry.New())
+			defer mirrorSrv.Close()
+			mirrorRepo := mirrorSrv.Listener.Addr().String() + "/e2e-index-mirror"
+
+			noneClient, err := containertools.NewClient(containertools.NoneTool)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			copier := indexer.NewIndexCopier(noneClient, logrus.WithFields(logrus.Fields{"package": packageName}))
+			_, err = copier.CopyIndex(ctx, indexer.CopyRequest{From: indexImage, To: mirrorRepo, ToTag: indexTag})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			origIndexImage := indexImage
+			indexImage = mirrorRepo + ":" + indexTag
+			err = exportWith(ctx, noneClient)
+			indexImage = origIndexImage
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "the mirrored index should be self-contained")
+
+			err = initialize()
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 		})
 	}
 
 	ginkgo.Context("using docker", func() {
-		IncludeSharedSpecs("docker")
+		IncludeSharedSpecs(containertools.DockerTool)
 	})
 
 	ginkgo.Context("using podman", func() {
-		IncludeSharedSpecs("podman")
+		IncludeSharedSpecs(containertools.PodmanTool)
+	})
+
+	ginkgo.Context("using no container runtime", func() {
+		var (
+			registrySrv                     *httptest.Server
+			origBundleImage, origIndexImage string
+		)
+
+		ginkgo.BeforeEach(func() {
+			registrySrv = httptest.NewServer(registry.New())
+			origBundleImage, origIndexImage = bundleImage, indexImage
+			bundleImage = registrySrv.Listener.Addr().String() + "/e2e-bundle"
+			indexImage = registrySrv.Listener.Addr().String() + "/e2e-index:" + indexTag
+		})
+
+		ginkgo.AfterEach(func() {
+			registrySrv.Close()
+			bundleImage, indexImage = origBundleImage, origIndexImage
+		})
+
+		ginkgo.It("builds and manipulates bundle and index images", func() {
+			ctx := context.Background()
+			client, err := containertools.NewClient(containertools.NoneTool)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			ginkgo.By("building and pushing bundles")
+			err = buildAndPushBundles(ctx, client)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			ginkgo.By("building and pushing an index")
+			err = buildAndPushIndex(ctx, client)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			ginkgo.By("exporting an index to disk")
+			err = exportWith(ctx, client)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			ginkgo.By("loading manifests from a directory")
+			err = initialize()
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		})
 	})
 })